@@ -1,11 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	crand "crypto/rand"
@@ -14,14 +14,20 @@ import (
 	mathrand "math/rand"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
+	qrcode "github.com/skip2/go-qrcode"
 
 	twilio "github.com/twilio/twilio-go"
-	openapi "github.com/twilio/twilio-go/rest/api/v2010"
 
 	"auth-service/backend"
 )
 
+const otpCacheTTL = 5 * time.Minute
+const defaultJWTTTL = 15 * time.Minute
+const maxOTPAttempts = 5
+
 // OTPRequest represents the request body for OTP generation
 type OTPRequest struct {
 	Phone string `json:"phone" binding:"required"`
@@ -33,10 +39,22 @@ type ValidateOTPRequest struct {
 	Phone string `json:"phone" binding:"required"`
 }
 
+// TOTPVerifyRequest represents the request body for TOTP verification
+type TOTPVerifyRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code"  binding:"required"`
+}
+
 var (
-	otpCache     backend.OTPCache
-	twilioClient *twilio.RestClient
-	fallbackRand *mathrand.Rand
+	otpCache           backend.OTPCache
+	totpStore          backend.TOTPStore
+	smsProvider        backend.SMSProvider
+	sessionIssuer      *backend.SessionIssuer
+	otpSendLimiter     backend.RateLimiter
+	otpValidateLimiter backend.RateLimiter
+	twilioClient       *twilio.RestClient
+	redisClient        *redis.Client
+	fallbackRand       *mathrand.Rand
 )
 
 func init() {
@@ -47,52 +65,212 @@ func init() {
 		}
 	}
 
-	// Initialize in-memory OTP cache
-	otpCache = backend.NewMemoryOTPCache()
+	// Initialize the OTP cache (memory or Redis, per OTP_STORE)
+	otpCache = newOTPCache()
+
+	// Rate limiters share the same backing store as the OTP cache
+	otpSendLimiter = newRateLimiter("otp:send:",
+		backend.RateLimitRule{Limit: 1, Window: 30 * time.Second},
+		backend.RateLimitRule{Limit: 3, Window: 10 * time.Minute},
+	)
+	otpValidateLimiter = newRateLimiter("otp:validate:",
+		backend.RateLimitRule{Limit: maxOTPAttempts, Window: otpCacheTTL},
+	)
+
+	// Initialize in-memory TOTP store for app-based second factors
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "AuthService"
+	}
+	totpStore = backend.NewMemoryTOTPStore(issuer)
 
 	twilioClient = twilio.NewRestClientWithParams(twilio.ClientParams{
 		Username: os.Getenv("TWILLIO_SID"),
 		Password: os.Getenv("TWILLIO_AUTH_TOKEN"),
 	})
 
+	// Select the SMS/voice provider implementation via env
+	switch os.Getenv("SMS_PROVIDER") {
+	case "twilio_verify":
+		smsProvider = backend.NewTwilioVerifyProvider(twilioClient, os.Getenv("TWILLIO_VERIFY_SERVICE_SID"))
+	case "test":
+		smsProvider = backend.NewTestSMSProvider()
+	default:
+		smsProvider = backend.NewTwilioMessagesProvider(twilioClient, os.Getenv("TWILLIO_PHONE"))
+	}
+
 	// Initialize local math/rand generator (used only as crypto fallback)
 	fallbackRand = mathrand.New(mathrand.NewSource(time.Now().UnixNano()))
+
+	var err error
+	sessionIssuer, err = newSessionIssuer()
+	if err != nil {
+		log.Fatalf("failed to initialize session issuer: %v", err)
+	}
+}
+
+// newOTPCache selects an OTPCache implementation based on OTP_STORE. Redis
+// unlocks horizontal scaling behind a load balancer, which the in-memory map
+// doesn't support since each instance would have its own copy.
+func newOTPCache() backend.OTPCache {
+	pepper := []byte(os.Getenv("OTP_PEPPER"))
+
+	if os.Getenv("OTP_STORE") != "redis" {
+		return backend.NewMemoryOTPCache(pepper)
+	}
+
+	opts, err := redis.ParseURL(os.Getenv("REDIS_URL"))
+	if err != nil {
+		log.Fatalf("invalid REDIS_URL: %v", err)
+	}
+	redisClient = redis.NewClient(opts)
+
+	return backend.NewRedisOTPCache(redisClient, "otp:", otpCacheTTL, pepper)
+}
+
+// newRateLimiter builds a RateLimiter enforcing rules, backed by the same
+// store as the OTP cache (memory or Redis).
+func newRateLimiter(keyPrefix string, rules ...backend.RateLimitRule) backend.RateLimiter {
+	if redisClient == nil {
+		return backend.NewMemoryRateLimiter(rules...)
+	}
+	return backend.NewRedisRateLimiter(redisClient, keyPrefix, rules...)
+}
+
+// newSessionIssuer builds the JWT issuer used to mint session tokens after a
+// successful OTP/TOTP validation. HS256 with a shared secret is the default;
+// set JWT_ALG=RS256 and JWT_RSA_PRIVATE_KEY_PATH/JWT_RSA_PUBLIC_KEY_PATH to
+// sign with an RSA keypair instead.
+func newSessionIssuer() (*backend.SessionIssuer, error) {
+	ttl := defaultJWTTTL
+	if raw := os.Getenv("JWT_TTL"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_TTL: %w", err)
+		}
+		ttl = parsed
+	}
+
+	if os.Getenv("JWT_ALG") == "RS256" {
+		privateKeyPEM, err := os.ReadFile(os.Getenv("JWT_RSA_PRIVATE_KEY_PATH"))
+		if err != nil {
+			return nil, fmt.Errorf("read JWT_RSA_PRIVATE_KEY_PATH: %w", err)
+		}
+		publicKeyPEM, err := os.ReadFile(os.Getenv("JWT_RSA_PUBLIC_KEY_PATH"))
+		if err != nil {
+			return nil, fmt.Errorf("read JWT_RSA_PUBLIC_KEY_PATH: %w", err)
+		}
+		return backend.NewRS256SessionIssuer(privateKeyPEM, publicKeyPEM, ttl)
+	}
+
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("JWT_SECRET must be set when JWT_ALG is not RS256")
+	}
+
+	return backend.NewHS256SessionIssuer([]byte(secret), ttl), nil
 }
 
 func main() {
 	r := gin.Default()
 
 	// Public routes
-	r.POST("/otp", generateOTP)
-	r.POST("/otp/validate", validateOTP)
+	r.POST("/otp", rateLimitByPhone(otpSendLimiter), generateOTP)
+	r.POST("/otp/validate", rateLimitValidateByPhone(), validateOTP)
+	// The TOTP fallback inside validateOTP is covered by the limiter above;
+	// the dedicated /totp/verify endpoint needs the same throttling since a
+	// 6-digit TOTP code is just as brute-forceable as an SMS OTP.
+	r.POST("/totp/verify", rateLimitByPhone(otpValidateLimiter), totpVerify)
+
+	// Authenticated routes: the caller must already hold a session JWT
+	// (minted after a successful OTP/TOTP validation), so enrollment can't
+	// be performed for a phone number the caller hasn't proven possession
+	// of.
+	r.POST("/refresh", backend.AuthMiddleware(sessionIssuer), refresh)
+	r.POST("/totp/enroll", backend.AuthMiddleware(sessionIssuer), totpEnroll)
 
 	r.Run(":8000")
 }
 
+// rateLimitByPhone throttles requests keyed by the phone field in the JSON
+// body, returning 429 with a Retry-After header once limiter is exhausted.
+func rateLimitByPhone(limiter backend.RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Phone string `json:"phone"`
+		}
+		if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil || req.Phone == "" {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter, err := limiter.Allow(c.Request.Context(), req.Phone)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitValidateByPhone caps verification attempts per phone within a
+// sliding otpCacheTTL window; once exhausted it deletes the pending OTP to
+// force a fresh one to be issued. Issuing a new OTP does not reset the window.
+func rateLimitValidateByPhone() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Phone string `json:"phone"`
+		}
+		if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil || req.Phone == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		allowed, retryAfter, err := otpValidateLimiter.Allow(ctx, req.Phone)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		if !allowed {
+			if delErr := otpCache.DeleteOTP(ctx, req.Phone); delErr != nil {
+				log.Printf("Failed to delete OTP from cache: %v", delErr)
+			}
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"valid": false, "error": "Too many attempts"})
+			return
+		}
+		c.Next()
+	}
+}
+
 func generateOTP(c *gin.Context) {
 	var req OTPRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx := c.Request.Context()
 
+	channel := backend.Channel(c.DefaultQuery("channel", string(backend.ChannelSMS)))
+
 	otp := generateSecureOTP()
 
-	params := &openapi.CreateMessageParams{}
-	// Ensure phone number is in E.164 format
-	formattedPhone := req.Phone
-	if !strings.HasPrefix(formattedPhone, "+") {
-		formattedPhone = "+" + formattedPhone
+	if err := smsProvider.SendOTP(ctx, req.Phone, otp, channel); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send OTP: " + err.Error()})
+		return
 	}
-	params.SetTo(formattedPhone)
-	params.SetFrom(os.Getenv("TWILLIO_PHONE"))
-	params.SetBody("Your OTP is: " + otp)
 
-	_, err := twilioClient.Api.CreateMessage(params)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send OTP: " + err.Error()})
+	// Providers that own verification themselves (e.g. Twilio Verify)
+	// generate their own code, so there's nothing to cache locally.
+	if smsProvider.OwnsVerification() {
+		c.JSON(http.StatusOK, gin.H{"message": "OTP sent successfully"})
 		return
 	}
 
@@ -107,28 +285,67 @@ func generateOTP(c *gin.Context) {
 
 func validateOTP(c *gin.Context) {
 	var req ValidateOTPRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": err.Error()})
 		return
 	}
 
 	ctx := c.Request.Context()
 
-	// Retrieve OTP from cache
-	storedOTP, err := otpCache.GetOTP(ctx, req.Phone)
+	// Providers that own verification themselves (e.g. Twilio Verify) skip
+	// OTPCache entirely.
+	if smsProvider.OwnsVerification() {
+		ok, err := smsProvider.VerifyOTP(ctx, req.Phone, req.Otp)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"valid": false, "error": "Internal server error"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusOK, gin.H{"valid": false, "error": "OTP is incorrect"})
+			return
+		}
+		issueSession(c, req.Phone)
+		return
+	}
+
+	// Compare against the cached OTP in constant time
+	matched, err := otpCache.VerifyOTP(ctx, req.Phone, req.Otp)
 	if err != nil {
 		switch err {
-		case backend.ErrOTPExpired:
-			c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": "OTP has expired"})
-		case backend.ErrOTPNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"valid": false, "error": "OTP not found"})
+		case backend.ErrOTPExpired, backend.ErrOTPNotFound:
+			// Fall through to TOTP: the phone may have an app-based
+			// authenticator enrolled instead of (or in addition to) SMS.
+			if totpErr := totpStore.Verify(ctx, req.Phone, req.Otp); totpErr == nil {
+				issueSession(c, req.Phone)
+				return
+			}
+			if err == backend.ErrOTPExpired {
+				c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": "OTP has expired"})
+			} else {
+				c.JSON(http.StatusNotFound, gin.H{"valid": false, "error": "OTP not found"})
+			}
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"valid": false, "error": "Internal server error"})
 		}
 		return
 	}
 
-	if storedOTP != req.Otp {
+	if !matched {
+		// Not the SMS code; check whether it's a valid TOTP code instead.
+		if totpErr := totpStore.Verify(ctx, req.Phone, req.Otp); totpErr == nil {
+			issueSession(c, req.Phone)
+			return
+		}
+
+		// A wrong guess counts against the OTP itself: once it hits
+		// maxOTPAttempts, invalidate it immediately rather than waiting for
+		// expiry.
+		if attempts, attemptErr := otpCache.IncrementAttempts(ctx, req.Phone); attemptErr == nil && attempts >= maxOTPAttempts {
+			if err := otpCache.DeleteOTP(ctx, req.Phone); err != nil {
+				log.Printf("Failed to delete OTP from cache: %v", err)
+			}
+		}
+
 		c.JSON(http.StatusOK, gin.H{"valid": false, "error": "OTP is incorrect"})
 		return
 	}
@@ -138,7 +355,83 @@ func validateOTP(c *gin.Context) {
 		log.Printf("Failed to delete OTP from cache: %v", err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"valid": true})
+	issueSession(c, req.Phone)
+}
+
+func totpEnroll(c *gin.Context) {
+	// Enrollment is scoped to the caller's own session subject (set by
+	// AuthMiddleware after a verified OTP/TOTP login), not a phone number
+	// taken from the request body, so a caller can't enroll a second
+	// factor for a phone they haven't proven possession of.
+	phone := c.GetString(backend.SubjectContextKey)
+
+	ctx := c.Request.Context()
+
+	secret, otpauthURL, err := totpStore.Enroll(ctx, phone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll TOTP"})
+		return
+	}
+
+	if c.Query("format") == "png" {
+		png, err := qrcode.Encode(otpauthURL, qrcode.Medium, 256)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+			return
+		}
+		c.Data(http.StatusOK, "image/png", png)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"secret": secret, "otpauth_url": otpauthURL})
+}
+
+func totpVerify(c *gin.Context) {
+	var req TOTPVerifyRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := totpStore.Verify(ctx, req.Phone, req.Code); err != nil {
+		switch err {
+		case backend.ErrTOTPNotEnrolled:
+			c.JSON(http.StatusNotFound, gin.H{"valid": false, "error": "TOTP not enrolled"})
+		case backend.ErrTOTPInvalidCode:
+			c.JSON(http.StatusOK, gin.H{"valid": false, "error": "TOTP code is incorrect"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"valid": false, "error": "Internal server error"})
+		}
+		return
+	}
+
+	issueSession(c, req.Phone)
+}
+
+// refresh exchanges a still-valid token (checked by AuthMiddleware) for a
+// freshly minted one.
+func refresh(c *gin.Context) {
+	subject := c.GetString(backend.SubjectContextKey)
+
+	token, err := sessionIssuer.IssueToken(subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue session token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// issueSession mints a session JWT for subject and writes it alongside the
+// validation result.
+func issueSession(c *gin.Context, subject string) {
+	token, err := sessionIssuer.IssueToken(subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"valid": false, "error": "Failed to issue session token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"valid": true, "token": token})
 }
 
 func generateSecureOTP() string {
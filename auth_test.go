@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"auth-service/backend"
+)
+
+func TestOTPSendAndValidateFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testProvider := backend.NewTestSMSProvider()
+	smsProvider = testProvider
+
+	r := gin.New()
+	r.POST("/otp", generateOTP)
+	r.POST("/otp/validate", validateOTP)
+
+	phone := "+15555550190"
+
+	sendBody, _ := json.Marshal(OTPRequest{Phone: phone})
+	req := httptest.NewRequest(http.MethodPost, "/otp", bytes.NewReader(sendBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("/otp: got status %d, body %s", w.Code, w.Body.String())
+	}
+
+	sent := testProvider.LastSent()
+	if sent == nil {
+		t.Fatal("expected TestSMSProvider to record a sent message")
+	}
+
+	validateBody, _ := json.Marshal(ValidateOTPRequest{Phone: phone, Otp: sent.Code})
+	req = httptest.NewRequest(http.MethodPost, "/otp/validate", bytes.NewReader(validateBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("/otp/validate: got status %d, body %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Valid bool   `json:"valid"`
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !resp.Valid {
+		t.Fatalf("expected valid=true, got body %s", w.Body.String())
+	}
+	if resp.Token == "" {
+		t.Fatal("expected a session token to be issued")
+	}
+}
+
+func TestValidateOTP_RejectsWrongCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	smsProvider = backend.NewTestSMSProvider()
+
+	r := gin.New()
+	r.POST("/otp", generateOTP)
+	r.POST("/otp/validate", validateOTP)
+
+	phone := "+15555550191"
+
+	sendBody, _ := json.Marshal(OTPRequest{Phone: phone})
+	req := httptest.NewRequest(http.MethodPost, "/otp", bytes.NewReader(sendBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("/otp: got status %d", w.Code)
+	}
+
+	validateBody, _ := json.Marshal(ValidateOTPRequest{Phone: phone, Otp: "000000"})
+	req = httptest.NewRequest(http.MethodPost, "/otp/validate", bytes.NewReader(validateBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp struct {
+		Valid bool `json:"valid"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Valid {
+		t.Fatal("expected valid=false for a wrong code")
+	}
+}
@@ -2,6 +2,7 @@ package backend
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"sync"
 	"time"
@@ -10,8 +11,22 @@ import (
 // OTPCache defines the interface for OTP storage operations
 type OTPCache interface {
 	SetOTP(ctx context.Context, phone string, otp string) error
+	// GetOTP returns an opaque verifier for the stored OTP (an HMAC digest,
+	// not the plaintext code).
+	//
+	// Deprecated: compare codes with VerifyOTP instead, which runs the
+	// comparison in constant time without handing the verifier to the
+	// caller.
 	GetOTP(ctx context.Context, phone string) (string, error)
+	// VerifyOTP reports whether candidate matches the OTP stored for phone,
+	// using a constant-time comparison so timing can't leak how many
+	// characters matched.
+	VerifyOTP(ctx context.Context, phone string, candidate string) (bool, error)
 	DeleteOTP(ctx context.Context, phone string) error
+	// IncrementAttempts records a failed verification attempt for phone and
+	// returns the new attempt count, so callers can invalidate an OTP after
+	// too many wrong guesses without waiting for it to expire.
+	IncrementAttempts(ctx context.Context, phone string) (int, error)
 }
 
 const (
@@ -24,21 +39,27 @@ var (
 	ErrOTPNotFound = errors.New("OTP not found")
 )
 
-// MemoryOTPCache implements OTPCache using in-memory storage
+// MemoryOTPCache implements OTPCache using in-memory storage. OTPs are
+// stored only as an HMAC digest keyed by a server-side pepper, so a memory
+// dump doesn't leak active codes.
 type MemoryOTPCache struct {
-	mu    sync.RWMutex
-	store map[string]otpEntry
+	mu     sync.RWMutex
+	pepper []byte
+	store  map[string]otpEntry
 }
 
 type otpEntry struct {
-	otp       string
+	otpHash   string
 	expiresAt time.Time
+	attempts  int
 }
 
-// NewMemoryOTPCache creates a new MemoryOTPCache instance
-func NewMemoryOTPCache() *MemoryOTPCache {
+// NewMemoryOTPCache creates a new MemoryOTPCache instance. pepper is mixed
+// into the stored OTP hash; it should come from a secret, e.g. OTP_PEPPER.
+func NewMemoryOTPCache(pepper []byte) *MemoryOTPCache {
 	cache := &MemoryOTPCache{
-		store: make(map[string]otpEntry),
+		pepper: pepper,
+		store:  make(map[string]otpEntry),
 	}
 
 	// Start cleanup goroutine
@@ -47,19 +68,21 @@ func NewMemoryOTPCache() *MemoryOTPCache {
 	return cache
 }
 
-// SetOTP stores the OTP in memory with expiration
+// SetOTP stores a hash of the OTP in memory with expiration.
 func (c *MemoryOTPCache) SetOTP(ctx context.Context, phone string, otp string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.store[phone] = otpEntry{
-		otp:       otp,
+		otpHash:   hashOTP(c.pepper, otp),
 		expiresAt: time.Now().Add(otpExpiration),
 	}
 	return nil
 }
 
-// GetOTP retrieves the OTP from memory
+// GetOTP returns the stored OTP's hash.
+//
+// Deprecated: use VerifyOTP instead.
 func (c *MemoryOTPCache) GetOTP(ctx context.Context, phone string) (string, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -74,7 +97,27 @@ func (c *MemoryOTPCache) GetOTP(ctx context.Context, phone string) (string, erro
 		return "", ErrOTPExpired
 	}
 
-	return entry.otp, nil
+	return entry.otpHash, nil
+}
+
+// VerifyOTP reports whether candidate matches the OTP stored for phone,
+// comparing hashes in constant time.
+func (c *MemoryOTPCache) VerifyOTP(ctx context.Context, phone string, candidate string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.store[phone]
+	if !exists {
+		return false, ErrOTPNotFound
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.store, phone)
+		return false, ErrOTPExpired
+	}
+
+	match := subtle.ConstantTimeCompare([]byte(hashOTP(c.pepper, candidate)), []byte(entry.otpHash)) == 1
+	return match, nil
 }
 
 // DeleteOTP removes the OTP from memory
@@ -86,6 +129,21 @@ func (c *MemoryOTPCache) DeleteOTP(ctx context.Context, phone string) error {
 	return nil
 }
 
+// IncrementAttempts records a failed verification attempt for phone.
+func (c *MemoryOTPCache) IncrementAttempts(ctx context.Context, phone string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.store[phone]
+	if !exists {
+		return 0, ErrOTPNotFound
+	}
+
+	entry.attempts++
+	c.store[phone] = entry
+	return entry.attempts, nil
+}
+
 // cleanup periodically removes expired OTPs
 func (c *MemoryOTPCache) cleanup() {
 	ticker := time.NewTicker(1 * time.Minute)
@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoryOTPCache_VerifyOTPMatchesAndRejects(t *testing.T) {
+	cache := NewMemoryOTPCache([]byte("pepper"))
+	ctx := context.Background()
+	phone := "+15555550150"
+
+	if err := cache.SetOTP(ctx, phone, "123456"); err != nil {
+		t.Fatalf("SetOTP: %v", err)
+	}
+
+	ok, err := cache.VerifyOTP(ctx, phone, "123456")
+	if err != nil {
+		t.Fatalf("VerifyOTP: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected matching OTP to verify")
+	}
+
+	ok, err = cache.VerifyOTP(ctx, phone, "000000")
+	if err != nil {
+		t.Fatalf("VerifyOTP: %v", err)
+	}
+	if ok {
+		t.Fatal("expected mismatched OTP to fail verification")
+	}
+}
+
+func TestMemoryOTPCache_VerifyOTPNotFound(t *testing.T) {
+	cache := NewMemoryOTPCache([]byte("pepper"))
+	ctx := context.Background()
+
+	if _, err := cache.VerifyOTP(ctx, "+15555550151", "123456"); err != ErrOTPNotFound {
+		t.Fatalf("got error %v, want ErrOTPNotFound", err)
+	}
+}
+
+func TestMemoryOTPCache_IncrementAttempts(t *testing.T) {
+	cache := NewMemoryOTPCache([]byte("pepper"))
+	ctx := context.Background()
+	phone := "+15555550152"
+
+	if err := cache.SetOTP(ctx, phone, "123456"); err != nil {
+		t.Fatalf("SetOTP: %v", err)
+	}
+
+	for want := 1; want <= 3; want++ {
+		got, err := cache.IncrementAttempts(ctx, phone)
+		if err != nil {
+			t.Fatalf("IncrementAttempts: %v", err)
+		}
+		if got != want {
+			t.Fatalf("got attempt count %d, want %d", got, want)
+		}
+	}
+}
+
+func TestMemoryOTPCache_SetOTPResetsAttempts(t *testing.T) {
+	cache := NewMemoryOTPCache([]byte("pepper"))
+	ctx := context.Background()
+	phone := "+15555550153"
+
+	if err := cache.SetOTP(ctx, phone, "111111"); err != nil {
+		t.Fatalf("SetOTP: %v", err)
+	}
+	if _, err := cache.IncrementAttempts(ctx, phone); err != nil {
+		t.Fatalf("IncrementAttempts: %v", err)
+	}
+
+	// A resend should give the caller a fresh run at the attempt limit.
+	if err := cache.SetOTP(ctx, phone, "222222"); err != nil {
+		t.Fatalf("SetOTP (resend): %v", err)
+	}
+
+	got, err := cache.IncrementAttempts(ctx, phone)
+	if err != nil {
+		t.Fatalf("IncrementAttempts: %v", err)
+	}
+	if got != 1 {
+		t.Fatalf("got attempt count %d after resend, want 1", got)
+	}
+}
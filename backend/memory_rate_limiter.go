@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRateLimiter implements RateLimiter using an in-memory sliding
+// window of call timestamps per key.
+type MemoryRateLimiter struct {
+	mu    sync.Mutex
+	rules []RateLimitRule
+	hits  map[string][]time.Time
+}
+
+// NewMemoryRateLimiter creates a new MemoryRateLimiter enforcing all of the
+// given rules.
+func NewMemoryRateLimiter(rules ...RateLimitRule) *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		rules: rules,
+		hits:  make(map[string][]time.Time),
+	}
+}
+
+// Allow reports whether key may proceed under every configured rule,
+// recording the call if so.
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	history := evictBefore(l.hits[key], now.Add(-longestWindow(l.rules)))
+
+	for _, rule := range l.rules {
+		windowStart := now.Add(-rule.Window)
+		within := withinWindow(history, windowStart)
+		if len(within) >= rule.Limit {
+			l.hits[key] = history
+			return false, rule.Window - now.Sub(within[0]), nil
+		}
+	}
+
+	l.hits[key] = append(history, now)
+	return true, 0, nil
+}
+
+// evictBefore drops timestamps older than cutoff, preserving order.
+func evictBefore(history []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(history) && history[i].Before(cutoff) {
+		i++
+	}
+	return history[i:]
+}
+
+// withinWindow returns the suffix of history at or after windowStart.
+func withinWindow(history []time.Time, windowStart time.Time) []time.Time {
+	i := 0
+	for i < len(history) && history[i].Before(windowStart) {
+		i++
+	}
+	return history[i:]
+}
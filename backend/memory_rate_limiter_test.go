@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimiter_AllowsUpToLimitThenThrottles(t *testing.T) {
+	limiter := NewMemoryRateLimiter(RateLimitRule{Limit: 2, Window: time.Minute})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := limiter.Allow(ctx, "+15555550100")
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if !allowed {
+			t.Fatalf("call %d: expected allowed", i)
+		}
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "+15555550100")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowed {
+		t.Fatal("expected third call within the window to be throttled")
+	}
+	if retryAfter <= 0 {
+		t.Fatalf("expected a positive retryAfter, got %v", retryAfter)
+	}
+}
+
+func TestMemoryRateLimiter_KeysAreIndependent(t *testing.T) {
+	limiter := NewMemoryRateLimiter(RateLimitRule{Limit: 1, Window: time.Minute})
+	ctx := context.Background()
+
+	if allowed, _, _ := limiter.Allow(ctx, "+15555550101"); !allowed {
+		t.Fatal("expected first call for key a to be allowed")
+	}
+	if allowed, _, _ := limiter.Allow(ctx, "+15555550102"); !allowed {
+		t.Fatal("expected first call for an unrelated key to be allowed")
+	}
+	if allowed, _, _ := limiter.Allow(ctx, "+15555550101"); allowed {
+		t.Fatal("expected second call for key a to be throttled")
+	}
+}
+
+func TestMemoryRateLimiter_EnforcesEveryRule(t *testing.T) {
+	limiter := NewMemoryRateLimiter(
+		RateLimitRule{Limit: 5, Window: time.Hour},
+		RateLimitRule{Limit: 1, Window: time.Minute},
+	)
+	ctx := context.Background()
+
+	if allowed, _, _ := limiter.Allow(ctx, "+15555550103"); !allowed {
+		t.Fatal("expected first call to be allowed")
+	}
+	// The hourly rule still has room, but the tighter per-minute rule doesn't.
+	if allowed, _, _ := limiter.Allow(ctx, "+15555550103"); allowed {
+		t.Fatal("expected the tighter per-minute rule to throttle the second call")
+	}
+}
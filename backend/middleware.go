@@ -0,0 +1,36 @@
+package backend
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubjectContextKey is the gin context key AuthMiddleware stores the
+// token's subject under.
+const SubjectContextKey = "subject"
+
+// AuthMiddleware returns a gin handler that parses the Authorization: Bearer
+// header, verifies the token against issuer, and injects the subject (phone
+// number) into the gin context under SubjectContextKey. Requests without a
+// valid token are rejected with 401.
+func AuthMiddleware(issuer *SessionIssuer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		tokenString, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		claims, err := issuer.ParseToken(tokenString)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			return
+		}
+
+		c.Set(SubjectContextKey, claims.Subject)
+		c.Next()
+	}
+}
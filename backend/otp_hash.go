@@ -0,0 +1,15 @@
+package backend
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// hashOTP returns a hex-encoded HMAC-SHA256 digest of otp keyed by pepper,
+// so a cache/store dump never exposes the plaintext code.
+func hashOTP(pepper []byte, otp string) string {
+	mac := hmac.New(sha256.New, pepper)
+	mac.Write([]byte(otp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
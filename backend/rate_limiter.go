@@ -0,0 +1,35 @@
+package backend
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimiter defines the interface for throttling actions keyed by an
+// arbitrary string (typically a phone number).
+type RateLimiter interface {
+	// Allow reports whether the action identified by key may proceed right
+	// now. When it may not, retryAfter indicates how long the caller should
+	// wait before trying again.
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// RateLimitRule caps a key to Limit calls within a sliding Window. A
+// RateLimiter may enforce several rules at once (e.g. "3 per 10 minutes"
+// and "1 per 30 seconds"); a key is throttled as soon as any rule is hit.
+type RateLimitRule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// longestWindow returns the widest window across rules, used to bound how
+// much history a limiter needs to retain.
+func longestWindow(rules []RateLimitRule) time.Duration {
+	var longest time.Duration
+	for _, rule := range rules {
+		if rule.Window > longest {
+			longest = rule.Window
+		}
+	}
+	return longest
+}
@@ -0,0 +1,112 @@
+package backend
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisOTPCache implements OTPCache using Redis, so that multiple service
+// instances behind a load balancer share the same OTP state. Expiry is
+// enforced by Redis itself, so unlike MemoryOTPCache there's no cleanup
+// goroutine to run. OTPs are stored only as an HMAC digest keyed by a
+// server-side pepper, so a Redis dump doesn't leak active codes.
+type RedisOTPCache struct {
+	client    *redis.Client
+	keyPrefix string
+	ttl       time.Duration
+	pepper    []byte
+}
+
+// NewRedisOTPCache creates a new RedisOTPCache instance. keyPrefix namespaces
+// keys (e.g. "otp:"), ttl controls how long a stored OTP remains valid, and
+// pepper is mixed into the stored OTP hash (e.g. from OTP_PEPPER).
+func NewRedisOTPCache(client *redis.Client, keyPrefix string, ttl time.Duration, pepper []byte) *RedisOTPCache {
+	return &RedisOTPCache{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+		pepper:    pepper,
+	}
+}
+
+// SetOTP stores a hash of the OTP in Redis with expiration. SET ... NX
+// ensures the write (and its expiry) happens atomically. Any attempt
+// counter left over from a previous OTP for this phone is cleared, so a
+// resend gives the caller a fresh run at maxOTPAttempts, matching
+// MemoryOTPCache (which resets attempts implicitly by replacing the whole
+// entry).
+func (c *RedisOTPCache) SetOTP(ctx context.Context, phone string, otp string) error {
+	hash := hashOTP(c.pepper, otp)
+	ok, err := c.client.SetNX(ctx, c.key(phone), hash, c.ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// An OTP is already pending for this phone; overwrite it so the
+		// caller's latest code (and its fresh TTL) wins.
+		if err := c.client.Set(ctx, c.key(phone), hash, c.ttl).Err(); err != nil {
+			return err
+		}
+	}
+	return c.client.Del(ctx, c.attemptsKey(phone)).Err()
+}
+
+// GetOTP returns the stored OTP's hash.
+//
+// Deprecated: use VerifyOTP instead.
+func (c *RedisOTPCache) GetOTP(ctx context.Context, phone string) (string, error) {
+	hash, err := c.client.Get(ctx, c.key(phone)).Result()
+	if err == redis.Nil {
+		return "", ErrOTPNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// VerifyOTP reports whether candidate matches the OTP stored for phone,
+// comparing hashes in constant time.
+func (c *RedisOTPCache) VerifyOTP(ctx context.Context, phone string, candidate string) (bool, error) {
+	hash, err := c.client.Get(ctx, c.key(phone)).Result()
+	if err == redis.Nil {
+		return false, ErrOTPNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	match := subtle.ConstantTimeCompare([]byte(hashOTP(c.pepper, candidate)), []byte(hash)) == 1
+	return match, nil
+}
+
+// DeleteOTP removes the OTP from Redis.
+func (c *RedisOTPCache) DeleteOTP(ctx context.Context, phone string) error {
+	return c.client.Del(ctx, c.key(phone), c.attemptsKey(phone)).Err()
+}
+
+// IncrementAttempts records a failed verification attempt for phone,
+// expiring the counter alongside the OTP itself.
+func (c *RedisOTPCache) IncrementAttempts(ctx context.Context, phone string) (int, error) {
+	count, err := c.client.Incr(ctx, c.attemptsKey(phone)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if err := c.client.Expire(ctx, c.attemptsKey(phone), c.ttl).Err(); err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// key namespaces a phone number under the configured prefix.
+func (c *RedisOTPCache) key(phone string) string {
+	return c.keyPrefix + phone
+}
+
+// attemptsKey namespaces the failed-attempt counter for a phone number.
+func (c *RedisOTPCache) attemptsKey(phone string) string {
+	return c.keyPrefix + "attempts:" + phone
+}
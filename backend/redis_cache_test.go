@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisOTPCache(t *testing.T) *RedisOTPCache {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisOTPCache(client, "otp:", otpExpiration, []byte("pepper"))
+}
+
+func TestRedisOTPCache_VerifyOTPMatchesAndRejects(t *testing.T) {
+	cache := newTestRedisOTPCache(t)
+	ctx := context.Background()
+	phone := "+15555550170"
+
+	if err := cache.SetOTP(ctx, phone, "123456"); err != nil {
+		t.Fatalf("SetOTP: %v", err)
+	}
+
+	ok, err := cache.VerifyOTP(ctx, phone, "123456")
+	if err != nil {
+		t.Fatalf("VerifyOTP: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected matching OTP to verify")
+	}
+
+	ok, err = cache.VerifyOTP(ctx, phone, "000000")
+	if err != nil {
+		t.Fatalf("VerifyOTP: %v", err)
+	}
+	if ok {
+		t.Fatal("expected mismatched OTP to fail verification")
+	}
+}
+
+func TestRedisOTPCache_VerifyOTPNotFound(t *testing.T) {
+	cache := newTestRedisOTPCache(t)
+
+	if _, err := cache.VerifyOTP(context.Background(), "+15555550171", "123456"); err != ErrOTPNotFound {
+		t.Fatalf("got error %v, want ErrOTPNotFound", err)
+	}
+}
+
+func TestRedisOTPCache_SetOTPResetsAttempts(t *testing.T) {
+	cache := newTestRedisOTPCache(t)
+	ctx := context.Background()
+	phone := "+15555550172"
+
+	if err := cache.SetOTP(ctx, phone, "111111"); err != nil {
+		t.Fatalf("SetOTP: %v", err)
+	}
+	if _, err := cache.IncrementAttempts(ctx, phone); err != nil {
+		t.Fatalf("IncrementAttempts: %v", err)
+	}
+
+	// A resend should give the caller a fresh run at the attempt limit,
+	// matching MemoryOTPCache.
+	if err := cache.SetOTP(ctx, phone, "222222"); err != nil {
+		t.Fatalf("SetOTP (resend): %v", err)
+	}
+
+	got, err := cache.IncrementAttempts(ctx, phone)
+	if err != nil {
+		t.Fatalf("IncrementAttempts: %v", err)
+	}
+	if got != 1 {
+		t.Fatalf("got attempt count %d after resend, want 1", got)
+	}
+}
+
+func TestRedisOTPCache_DeleteOTPRemovesAttempts(t *testing.T) {
+	cache := newTestRedisOTPCache(t)
+	ctx := context.Background()
+	phone := "+15555550173"
+
+	if err := cache.SetOTP(ctx, phone, "123456"); err != nil {
+		t.Fatalf("SetOTP: %v", err)
+	}
+	if _, err := cache.IncrementAttempts(ctx, phone); err != nil {
+		t.Fatalf("IncrementAttempts: %v", err)
+	}
+	if err := cache.DeleteOTP(ctx, phone); err != nil {
+		t.Fatalf("DeleteOTP: %v", err)
+	}
+
+	if _, err := cache.VerifyOTP(ctx, phone, "123456"); err != ErrOTPNotFound {
+		t.Fatalf("got error %v, want ErrOTPNotFound", err)
+	}
+
+	got, err := cache.IncrementAttempts(ctx, phone)
+	if err != nil {
+		t.Fatalf("IncrementAttempts: %v", err)
+	}
+	if got != 1 {
+		t.Fatalf("got attempt count %d after delete, want 1", got)
+	}
+}
@@ -0,0 +1,90 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRateLimiter implements RateLimiter using a Redis sorted set per key,
+// scored by call timestamp, so limits are shared across instances.
+type RedisRateLimiter struct {
+	client    *redis.Client
+	keyPrefix string
+	rules     []RateLimitRule
+}
+
+// NewRedisRateLimiter creates a new RedisRateLimiter enforcing all of the
+// given rules.
+func NewRedisRateLimiter(client *redis.Client, keyPrefix string, rules ...RateLimitRule) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client:    client,
+		keyPrefix: keyPrefix,
+		rules:     rules,
+	}
+}
+
+// slidingWindowScript evicts expired entries, checks every rule, and (only if
+// every rule still has room) records the call, all inside a single Lua
+// script. Running the check-then-act as one EVAL makes it atomic against
+// concurrent callers, unlike separate ZRangeByScore/ZAdd round trips, which
+// let concurrent requests all observe the same pre-write count and race past
+// the limit.
+//
+// KEYS[1]   = sorted-set key
+// ARGV[1]   = now, as nanoseconds since epoch
+// ARGV[2]   = longest rule window, in nanoseconds (bounds eviction + TTL)
+// ARGV[3..] = pairs of (window_ns, limit) for each rule, in configured order
+//
+// Returns {allowed (0/1), retry_after_ns}.
+const slidingWindowScript = `
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local longest = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - longest)
+
+for i = 3, #ARGV, 2 do
+	local window = tonumber(ARGV[i])
+	local limit = tonumber(ARGV[i + 1])
+	local entries = redis.call('ZRANGEBYSCORE', key, now - window, '+inf', 'WITHSCORES')
+	if (#entries / 2) >= limit then
+		local oldest = tonumber(entries[2])
+		return {0, window - (now - oldest)}
+	end
+end
+
+redis.call('ZADD', key, now, now)
+redis.call('PEXPIRE', key, math.ceil(longest / 1e6))
+return {1, 0}
+`
+
+// Allow reports whether key may proceed under every configured rule,
+// recording the call if so.
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	redisKey := l.keyPrefix + key
+	now := time.Now()
+	longest := longestWindow(l.rules)
+
+	args := make([]interface{}, 0, 2+2*len(l.rules))
+	args = append(args, now.UnixNano(), longest.Nanoseconds())
+	for _, rule := range l.rules {
+		args = append(args, rule.Window.Nanoseconds(), rule.Limit)
+	}
+
+	result, err := l.client.Eval(ctx, slidingWindowScript, []string{redisKey}, args...).Result()
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected sliding window script result: %v", result)
+	}
+	allowed := values[0].(int64) == 1
+	retryAfter := time.Duration(values[1].(int64))
+
+	return allowed, retryAfter, nil
+}
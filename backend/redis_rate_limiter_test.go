@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisRateLimiter(t *testing.T, rules ...RateLimitRule) *RedisRateLimiter {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisRateLimiter(client, "ratelimit:", rules...)
+}
+
+func TestRedisRateLimiter_AllowsUpToLimitThenThrottles(t *testing.T) {
+	limiter := newTestRedisRateLimiter(t, RateLimitRule{Limit: 2, Window: time.Minute})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := limiter.Allow(ctx, "+15555550180")
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if !allowed {
+			t.Fatalf("call %d: expected allowed", i)
+		}
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "+15555550180")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowed {
+		t.Fatal("expected third call within the window to be throttled")
+	}
+	if retryAfter <= 0 {
+		t.Fatalf("expected a positive retryAfter, got %v", retryAfter)
+	}
+}
+
+func TestRedisRateLimiter_EnforcesEveryRule(t *testing.T) {
+	limiter := newTestRedisRateLimiter(t,
+		RateLimitRule{Limit: 5, Window: time.Hour},
+		RateLimitRule{Limit: 1, Window: time.Minute},
+	)
+	ctx := context.Background()
+
+	if allowed, _, _ := limiter.Allow(ctx, "+15555550181"); !allowed {
+		t.Fatal("expected first call to be allowed")
+	}
+	if allowed, _, _ := limiter.Allow(ctx, "+15555550181"); allowed {
+		t.Fatal("expected the tighter per-minute rule to throttle the second call")
+	}
+}
+
+// TestRedisRateLimiter_ConcurrentCallsDontRaceThePastLimit reproduces the
+// check-then-act race a non-atomic Allow would have: firing far more
+// concurrent requests than the limit allows must still only let Limit of
+// them through, since the check and the record now happen inside a single
+// Lua script rather than as separate round trips.
+func TestRedisRateLimiter_ConcurrentCallsDontRaceThePastLimit(t *testing.T) {
+	limiter := newTestRedisRateLimiter(t, RateLimitRule{Limit: 3, Window: time.Minute})
+	ctx := context.Background()
+	phone := "+15555550182"
+
+	const concurrency = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowedCount := 0
+
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			allowed, _, err := limiter.Allow(ctx, phone)
+			if err != nil {
+				t.Errorf("Allow: %v", err)
+				return
+			}
+			if allowed {
+				mu.Lock()
+				allowedCount++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowedCount != 3 {
+		t.Fatalf("got %d allowed calls out of %d concurrent requests, want exactly 3", allowedCount, concurrency)
+	}
+}
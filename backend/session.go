@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Public errors returned by SessionIssuer operations
+var (
+	ErrTokenInvalid = errors.New("token is invalid")
+	ErrTokenExpired = errors.New("token has expired")
+)
+
+// SessionClaims are the JWT claims minted after a successful OTP/TOTP
+// validation.
+type SessionClaims struct {
+	jwt.RegisteredClaims
+}
+
+// SessionIssuer mints and verifies the JWTs handed out after OTP
+// validation. It supports HS256 (a shared secret) or RS256 (a PEM keypair).
+type SessionIssuer struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	ttl       time.Duration
+}
+
+// NewHS256SessionIssuer creates a SessionIssuer that signs and verifies
+// tokens with a shared secret.
+func NewHS256SessionIssuer(secret []byte, ttl time.Duration) *SessionIssuer {
+	return &SessionIssuer{
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+		ttl:       ttl,
+	}
+}
+
+// NewRS256SessionIssuer creates a SessionIssuer that signs tokens with an RSA
+// private key and verifies them with the corresponding public key, both in
+// PEM form.
+func NewRS256SessionIssuer(privateKeyPEM, publicKeyPEM []byte, ttl time.Duration) (*SessionIssuer, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("parse RS256 private key: %w", err)
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("parse RS256 public key: %w", err)
+	}
+
+	return &SessionIssuer{
+		method:    jwt.SigningMethodRS256,
+		signKey:   privateKey,
+		verifyKey: publicKey,
+		ttl:       ttl,
+	}, nil
+}
+
+// IssueToken mints a signed JWT for subject (the validated phone number).
+func (s *SessionIssuer) IssueToken(subject string) (string, error) {
+	jti, err := newJTI()
+	if err != nil {
+		return "", fmt.Errorf("generate jti: %w", err)
+	}
+
+	now := time.Now()
+	claims := SessionClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+			ID:        jti,
+		},
+	}
+
+	token := jwt.NewWithClaims(s.method, claims)
+	return token.SignedString(s.signKey)
+}
+
+// ParseToken verifies tokenString's signature and expiry and returns its
+// claims.
+func (s *SessionIssuer) ParseToken(tokenString string) (*SessionClaims, error) {
+	claims := &SessionClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != s.method {
+			return nil, ErrTokenInvalid
+		}
+		return s.verifyKey, nil
+	})
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrTokenInvalid
+	}
+	if !token.Valid {
+		return nil, ErrTokenInvalid
+	}
+
+	return claims, nil
+}
+
+// newJTI generates a random token identifier.
+func newJTI() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
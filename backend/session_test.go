@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionIssuer_IssueAndParseRoundTrip(t *testing.T) {
+	issuer := NewHS256SessionIssuer([]byte("test-secret"), time.Minute)
+
+	token, err := issuer.IssueToken("+15555550199")
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+
+	claims, err := issuer.ParseToken(token)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	if claims.Subject != "+15555550199" {
+		t.Fatalf("got subject %q, want %q", claims.Subject, "+15555550199")
+	}
+	if claims.ID == "" {
+		t.Fatal("expected a non-empty jti")
+	}
+}
+
+func TestSessionIssuer_RejectsTokenFromDifferentSecret(t *testing.T) {
+	issuer := NewHS256SessionIssuer([]byte("test-secret"), time.Minute)
+	other := NewHS256SessionIssuer([]byte("different-secret"), time.Minute)
+
+	token, err := issuer.IssueToken("+15555550199")
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+
+	if _, err := other.ParseToken(token); err != ErrTokenInvalid {
+		t.Fatalf("got error %v, want ErrTokenInvalid", err)
+	}
+}
+
+func TestSessionIssuer_RejectsExpiredToken(t *testing.T) {
+	issuer := NewHS256SessionIssuer([]byte("test-secret"), -time.Minute)
+
+	token, err := issuer.IssueToken("+15555550199")
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+
+	if _, err := issuer.ParseToken(token); err != ErrTokenExpired {
+		t.Fatalf("got error %v, want ErrTokenExpired", err)
+	}
+}
@@ -0,0 +1,37 @@
+package backend
+
+import (
+	"context"
+	"errors"
+)
+
+// Channel identifies the delivery channel used to send an OTP.
+type Channel string
+
+const (
+	ChannelSMS      Channel = "sms"
+	ChannelVoice    Channel = "voice"
+	ChannelWhatsApp Channel = "whatsapp"
+)
+
+// ErrProviderVerificationNotSupported is returned by VerifyOTP on providers
+// that only deliver codes and rely on the caller (typically OTPCache) to
+// verify them.
+var ErrProviderVerificationNotSupported = errors.New("provider does not support remote verification")
+
+// SMSProvider abstracts over how OTP codes are delivered, and, for providers
+// that own their own verification lifecycle (e.g. Twilio Verify), checked.
+type SMSProvider interface {
+	// SendOTP delivers code to phone over channel.
+	SendOTP(ctx context.Context, phone string, code string, channel Channel) error
+	// VerifyOTP checks a user-submitted code against the provider's own
+	// record of it. Only meaningful when OwnsVerification reports true;
+	// providers that just deliver codes generated locally return
+	// ErrProviderVerificationNotSupported so the caller falls back to
+	// OTPCache-based comparison.
+	VerifyOTP(ctx context.Context, phone string, code string) (bool, error)
+	// OwnsVerification reports whether this provider verifies codes itself
+	// (e.g. Twilio Verify), so callers can skip OTPCache storage and avoid
+	// spending a live verification check just to probe which path to take.
+	OwnsVerification() bool
+}
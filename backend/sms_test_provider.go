@@ -0,0 +1,57 @@
+package backend
+
+import (
+	"context"
+	"sync"
+)
+
+// SentMessage records a single SendOTP call made against a TestSMSProvider.
+type SentMessage struct {
+	Phone   string
+	Code    string
+	Channel Channel
+}
+
+// TestSMSProvider is a no-op SMSProvider for tests: it never calls out to a
+// real carrier, it just records what it was asked to send so tests can
+// assert on it. Verification is left to OTPCache, same as the Twilio
+// Messages provider.
+type TestSMSProvider struct {
+	mu   sync.Mutex
+	Sent []SentMessage
+}
+
+// NewTestSMSProvider creates a new TestSMSProvider instance.
+func NewTestSMSProvider() *TestSMSProvider {
+	return &TestSMSProvider{}
+}
+
+// SendOTP records the message instead of delivering it.
+func (p *TestSMSProvider) SendOTP(ctx context.Context, phone string, code string, channel Channel) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Sent = append(p.Sent, SentMessage{Phone: phone, Code: code, Channel: channel})
+	return nil
+}
+
+// VerifyOTP always defers to OTPCache-based comparison.
+func (p *TestSMSProvider) VerifyOTP(ctx context.Context, phone string, code string) (bool, error) {
+	return false, ErrProviderVerificationNotSupported
+}
+
+// OwnsVerification reports false: TestSMSProvider never verifies codes
+// itself, leaving that to OTPCache.
+func (p *TestSMSProvider) OwnsVerification() bool {
+	return false
+}
+
+// LastSent returns the most recently recorded message, or nil if none.
+func (p *TestSMSProvider) LastSent() *SentMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.Sent) == 0 {
+		return nil
+	}
+	last := p.Sent[len(p.Sent)-1]
+	return &last
+}
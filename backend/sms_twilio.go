@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	twilio "github.com/twilio/twilio-go"
+	openapi "github.com/twilio/twilio-go/rest/api/v2010"
+	verifyapi "github.com/twilio/twilio-go/rest/verify/v2"
+)
+
+// TwilioMessagesProvider delivers OTPs via Twilio's Messages (SMS/WhatsApp)
+// and Calls (voice) APIs. It generates no codes itself and leaves
+// verification to the caller's OTPCache.
+type TwilioMessagesProvider struct {
+	client *twilio.RestClient
+	from   string
+}
+
+// NewTwilioMessagesProvider creates a new TwilioMessagesProvider instance.
+// from is the Twilio-owned number messages and calls are sent from.
+func NewTwilioMessagesProvider(client *twilio.RestClient, from string) *TwilioMessagesProvider {
+	return &TwilioMessagesProvider{client: client, from: from}
+}
+
+// SendOTP delivers code to phone over channel using Twilio's Messages or
+// Calls APIs depending on the channel.
+func (p *TwilioMessagesProvider) SendOTP(ctx context.Context, phone string, code string, channel Channel) error {
+	to := formatE164(phone)
+
+	switch channel {
+	case ChannelVoice:
+		params := &openapi.CreateCallParams{}
+		params.SetTo(to)
+		params.SetFrom(p.from)
+		params.SetTwiml(fmt.Sprintf("<Response><Say>Your verification code is %s</Say></Response>", code))
+		_, err := p.client.Api.CreateCall(params)
+		return err
+	case ChannelWhatsApp:
+		params := &openapi.CreateMessageParams{}
+		params.SetTo("whatsapp:" + to)
+		params.SetFrom("whatsapp:" + p.from)
+		params.SetBody("Your OTP is: " + code)
+		_, err := p.client.Api.CreateMessage(params)
+		return err
+	default:
+		params := &openapi.CreateMessageParams{}
+		params.SetTo(to)
+		params.SetFrom(p.from)
+		params.SetBody("Your OTP is: " + code)
+		_, err := p.client.Api.CreateMessage(params)
+		return err
+	}
+}
+
+// VerifyOTP is not supported: codes are generated locally, so comparison
+// happens against OTPCache instead.
+func (p *TwilioMessagesProvider) VerifyOTP(ctx context.Context, phone string, code string) (bool, error) {
+	return false, ErrProviderVerificationNotSupported
+}
+
+// OwnsVerification reports false: codes are generated and checked locally
+// via OTPCache.
+func (p *TwilioMessagesProvider) OwnsVerification() bool {
+	return false
+}
+
+// TwilioVerifyProvider delegates both code generation and verification to a
+// Twilio Verify service, bypassing OTPCache entirely.
+type TwilioVerifyProvider struct {
+	client     *twilio.RestClient
+	serviceSID string
+}
+
+// NewTwilioVerifyProvider creates a new TwilioVerifyProvider instance bound
+// to the given Verify Service SID.
+func NewTwilioVerifyProvider(client *twilio.RestClient, serviceSID string) *TwilioVerifyProvider {
+	return &TwilioVerifyProvider{client: client, serviceSID: serviceSID}
+}
+
+// SendOTP asks Twilio Verify to generate and deliver a code; the code
+// parameter is ignored since Twilio owns generation.
+func (p *TwilioVerifyProvider) SendOTP(ctx context.Context, phone string, _ string, channel Channel) error {
+	params := &verifyapi.CreateVerificationParams{}
+	params.SetTo(formatE164(phone))
+	params.SetChannel(string(channel))
+
+	_, err := p.client.VerifyV2.CreateVerification(p.serviceSID, params)
+	return err
+}
+
+// VerifyOTP asks Twilio Verify to check code against the verification it
+// issued for phone.
+func (p *TwilioVerifyProvider) VerifyOTP(ctx context.Context, phone string, code string) (bool, error) {
+	params := &verifyapi.CreateVerificationCheckParams{}
+	params.SetTo(formatE164(phone))
+	params.SetCode(code)
+
+	resp, err := p.client.VerifyV2.CreateVerificationCheck(p.serviceSID, params)
+	if err != nil {
+		return false, err
+	}
+	return resp.Status != nil && *resp.Status == "approved", nil
+}
+
+// OwnsVerification reports true: Twilio Verify generates and checks codes
+// itself, so the caller should skip OTPCache entirely.
+func (p *TwilioVerifyProvider) OwnsVerification() bool {
+	return true
+}
+
+// formatE164 ensures phone is in E.164 format, prefixing it with "+" if
+// the caller omitted it.
+func formatE164(phone string) string {
+	if strings.HasPrefix(phone, "+") {
+		return phone
+	}
+	return "+" + phone
+}
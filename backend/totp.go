@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+const (
+	// totpPeriod is the validity window, in seconds, of a single TOTP step (RFC 6238 default).
+	totpPeriod = 30
+	// totpSkewSteps allows codes from the previous/next step to account for clock drift.
+	totpSkewSteps = 1
+)
+
+// GenerateSecret returns a new random base32-encoded TOTP secret.
+func GenerateSecret(randReader func([]byte) (int, error)) (string, error) {
+	raw := make([]byte, 20)
+	if _, err := randReader(raw); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw), nil
+}
+
+// generateHOTP implements RFC 4226 HOTP over the given counter.
+func generateHOTP(secret []byte, counter uint64, digits int) string {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, counter)
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(buf)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := (uint32(sum[offset]&0x7f) << 24) |
+		(uint32(sum[offset+1]) << 16) |
+		(uint32(sum[offset+2]) << 8) |
+		uint32(sum[offset+3])
+
+	mod := uint32(math.Pow10(digits))
+	return fmt.Sprintf("%0*d", digits, code%mod)
+}
+
+// generateTOTP implements RFC 6238 TOTP: HOTP keyed by the current 30s time step.
+func generateTOTP(secret []byte, unixTime int64, digits int) string {
+	counter := uint64(unixTime / totpPeriod)
+	return generateHOTP(secret, counter, digits)
+}
+
+// validateTOTP checks candidate against the current time step and the
+// previous/next step to tolerate clock skew between client and server.
+func validateTOTP(secret []byte, candidate string, unixTime int64, digits int) bool {
+	matched, _ := validateTOTPStep(secret, candidate, unixTime, digits)
+	return matched
+}
+
+// validateTOTPStep is like validateTOTP but also returns the matched time
+// step counter, so a caller can reject replays of an already-consumed code
+// within the same skew window.
+func validateTOTPStep(secret []byte, candidate string, unixTime int64, digits int) (bool, int64) {
+	counter := unixTime / totpPeriod
+	for skew := -totpSkewSteps; skew <= totpSkewSteps; skew++ {
+		step := counter + int64(skew)
+		if generateHOTP(secret, uint64(step), digits) == candidate {
+			return true, step
+		}
+	}
+	return false, 0
+}
+
+// decodeSecret decodes a base32 TOTP secret as stored by TOTPStore.
+func decodeSecret(secret string) ([]byte, error) {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+}
@@ -0,0 +1,125 @@
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const defaultTOTPDigits = 6
+
+// Public errors returned by TOTPStore operations
+var (
+	ErrTOTPNotEnrolled = errors.New("TOTP not enrolled for subject")
+	ErrTOTPInvalidCode = errors.New("TOTP code is invalid")
+)
+
+// TOTPStore defines the interface for enrolling and validating app-based
+// (TOTP/HOTP) second factors, keyed by an arbitrary subject (typically phone).
+type TOTPStore interface {
+	// Enroll generates and persists a new secret for subject, returning the
+	// base32 secret and an otpauth:// URL suitable for rendering as a QR code.
+	Enroll(ctx context.Context, subject string) (secret string, otpauthURL string, err error)
+	// Verify checks code against the enrolled secret for subject.
+	Verify(ctx context.Context, subject string, code string) error
+	// Disable removes any enrolled secret for subject.
+	Disable(ctx context.Context, subject string) error
+}
+
+// totpRecord mirrors the subject/secret/digits schema used to persist an
+// enrolled authenticator. lastCounter is the most recently accepted time
+// step, so a captured code can't be replayed within its skew window;
+// lastCounterUsed tracks whether a code has ever been accepted, since a zero lastCounter is
+// also a valid (if ancient) time step.
+type totpRecord struct {
+	subject         string
+	secret          string
+	digits          int
+	lastCounter     int64
+	lastCounterUsed bool
+}
+
+// MemoryTOTPStore implements TOTPStore using in-memory storage.
+type MemoryTOTPStore struct {
+	mu     sync.RWMutex
+	issuer string
+	store  map[string]totpRecord
+}
+
+// NewMemoryTOTPStore creates a new MemoryTOTPStore instance. issuer is used
+// when building otpauth:// enrollment URLs (shown in authenticator apps).
+func NewMemoryTOTPStore(issuer string) *MemoryTOTPStore {
+	return &MemoryTOTPStore{
+		issuer: issuer,
+		store:  make(map[string]totpRecord),
+	}
+}
+
+// Enroll generates and stores a new secret for subject.
+func (s *MemoryTOTPStore) Enroll(ctx context.Context, subject string) (string, string, error) {
+	secret, err := GenerateSecret(rand.Read)
+	if err != nil {
+		return "", "", fmt.Errorf("generate TOTP secret: %w", err)
+	}
+
+	s.mu.Lock()
+	s.store[subject] = totpRecord{subject: subject, secret: secret, digits: defaultTOTPDigits}
+	s.mu.Unlock()
+
+	return secret, buildOTPAuthURL(s.issuer, subject, secret, defaultTOTPDigits), nil
+}
+
+// Verify checks code against the enrolled secret for subject, accepting the
+// previous and next time step to allow for clock skew. A code is rejected if
+// its time step has already been consumed, so a captured code can't be
+// replayed for the rest of its ~90s acceptance window.
+func (s *MemoryTOTPStore) Verify(ctx context.Context, subject string, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.store[subject]
+	if !ok {
+		return ErrTOTPNotEnrolled
+	}
+
+	secret, err := decodeSecret(rec.secret)
+	if err != nil {
+		return fmt.Errorf("decode TOTP secret: %w", err)
+	}
+
+	matched, counter := validateTOTPStep(secret, code, time.Now().Unix(), rec.digits)
+	if !matched || (rec.lastCounterUsed && counter <= rec.lastCounter) {
+		return ErrTOTPInvalidCode
+	}
+
+	rec.lastCounter = counter
+	rec.lastCounterUsed = true
+	s.store[subject] = rec
+
+	return nil
+}
+
+// Disable removes any enrolled secret for subject.
+func (s *MemoryTOTPStore) Disable(ctx context.Context, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.store, subject)
+	return nil
+}
+
+// buildOTPAuthURL builds the otpauth:// URI consumed by authenticator apps.
+func buildOTPAuthURL(issuer, subject, secret string, digits int) string {
+	label := url.PathEscape(fmt.Sprintf("%s:%s", issuer, subject))
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("digits", fmt.Sprintf("%d", digits))
+	q.Set("period", fmt.Sprintf("%d", totpPeriod))
+	q.Set("algorithm", "SHA1")
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, q.Encode())
+}
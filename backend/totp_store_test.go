@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryTOTPStore_RejectsReplayedCode(t *testing.T) {
+	store := NewMemoryTOTPStore("TestIssuer")
+	ctx := context.Background()
+	subject := "+15555550160"
+
+	secretB32, _, err := store.Enroll(ctx, subject)
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+
+	secret, err := decodeSecret(secretB32)
+	if err != nil {
+		t.Fatalf("decodeSecret: %v", err)
+	}
+
+	code := generateTOTP(secret, time.Now().Unix(), defaultTOTPDigits)
+
+	if err := store.Verify(ctx, subject, code); err != nil {
+		t.Fatalf("expected first use to succeed, got %v", err)
+	}
+
+	if err := store.Verify(ctx, subject, code); err != ErrTOTPInvalidCode {
+		t.Fatalf("expected replay of the same code to be rejected, got %v", err)
+	}
+}
+
+func TestMemoryTOTPStore_VerifyUnenrolledSubject(t *testing.T) {
+	store := NewMemoryTOTPStore("TestIssuer")
+
+	if err := store.Verify(context.Background(), "+15555550161", "123456"); err != ErrTOTPNotEnrolled {
+		t.Fatalf("got error %v, want ErrTOTPNotEnrolled", err)
+	}
+}
@@ -0,0 +1,56 @@
+package backend
+
+import "testing"
+
+// RFC 4226 Appendix D test vectors (secret "12345678901234567890", ASCII).
+func TestGenerateHOTP_RFC4226Vectors(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	want := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+
+	for counter, expected := range want {
+		got := generateHOTP(secret, uint64(counter), 6)
+		if got != expected {
+			t.Errorf("counter %d: got %s, want %s", counter, got, expected)
+		}
+	}
+}
+
+// RFC 6238 Appendix B test vector at T=59s (counter 1), 8 digits.
+func TestGenerateTOTP_RFC6238Vector(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	got := generateTOTP(secret, 59, 8)
+	want := "94287082"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestValidateTOTP_AcceptsAdjacentStepForClockSkew(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	code := generateTOTP(secret, 59, 8)
+
+	if !validateTOTP(secret, code, 59+totpPeriod, 8) {
+		t.Fatal("expected code from the previous step to validate")
+	}
+}
+
+func TestValidateTOTP_RejectsOutOfWindowStep(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	code := generateTOTP(secret, 59, 8)
+
+	if validateTOTP(secret, code, 59+3*totpPeriod, 8) {
+		t.Fatal("expected code three steps away to be rejected")
+	}
+}
+
+func TestValidateTOTP_RejectsWrongCode(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	if validateTOTP(secret, "00000000", 59, 8) {
+		t.Fatal("expected wrong code to be rejected")
+	}
+}